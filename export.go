@@ -0,0 +1,125 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+)
+
+// HTML returns the given page reflowed as a fragment of HTML, built from
+// the page's structured text (FZ_STEXT_PRESERVE_IMAGES|FZ_STEXT_PRESERVE_LIGATURES).
+// Index starts at 0.
+func (f *Document) HTML(pageNumber int) (string, error) {
+	return f.stextAsID(pageNumber, func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page, id int) {
+		C.fz_print_stext_page_as_html(ctx, out, stext, C.int(id))
+	})
+}
+
+// XHTML returns the given page reflowed as XHTML. Index starts at 0.
+func (f *Document) XHTML(pageNumber int) (string, error) {
+	return f.stextAsID(pageNumber, func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page, id int) {
+		C.fz_print_stext_page_as_xhtml(ctx, out, stext, C.int(id))
+	})
+}
+
+// HTMLAll returns every page reflowed into a single, scrollable HTML
+// document, bookended with fz_print_stext_header_as_html/_trailer.
+func (f *Document) HTMLAll() (string, error) {
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	buf := C.fz_new_buffer(ctx, 8192)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	out := C.fz_new_output_with_buffer(ctx, buf)
+	defer C.fz_drop_output(ctx, out)
+
+	C.fz_print_stext_header_as_html(ctx, out)
+
+	for n := 0; n < f.pageTotal; n++ {
+		page := C.pdf_load_page(ctx, f.pdf, C.int(n))
+
+		var bounds C.fz_rect
+		C.pdf_bound_page(ctx, page, &bounds)
+
+		var ctm C.fz_matrix
+		C.fz_scale(&ctm, C.float(1.0), C.float(1.0))
+
+		stext := C.fz_new_stext_page(ctx, &bounds)
+
+		var opts C.fz_stext_options
+		opts.flags = C.FZ_STEXT_PRESERVE_IMAGES | C.FZ_STEXT_PRESERVE_LIGATURES
+
+		device := C.fz_new_stext_device(ctx, stext, &opts)
+
+		var cookie C.fz_cookie
+		C.pdf_run_page(ctx, page, device, &ctm, &cookie)
+
+		C.fz_close_device(ctx, device)
+		C.fz_drop_device(ctx, device)
+
+		C.fz_print_stext_page_as_html(ctx, out, stext, C.int(n))
+
+		C.fz_drop_stext_page(ctx, stext)
+		C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
+	}
+
+	C.fz_print_stext_trailer_as_html(ctx, out)
+	C.fz_close_output(ctx, out)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	return C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size)), nil
+}
+
+// SVG returns the given page rendered as a standalone SVG document. Index
+// starts at 0.
+func (f *Document) SVG(pageNumber int) (string, error) {
+	if pageNumber < 0 || f.pageTotal <= pageNumber {
+		return "", ErrPageMissing
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	page := C.pdf_load_page(ctx, f.pdf, C.int(pageNumber))
+	defer C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
+
+	var bounds C.fz_rect
+	C.pdf_bound_page(ctx, page, &bounds)
+
+	var ctm C.fz_matrix
+	C.fz_scale(&ctm, C.float(1.0), C.float(1.0))
+
+	buf := C.fz_new_buffer(ctx, 8192)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	out := C.fz_new_output_with_buffer(ctx, buf)
+	defer C.fz_drop_output(ctx, out)
+
+	width := C.float(bounds.x1 - bounds.x0)
+	height := C.float(bounds.y1 - bounds.y0)
+
+	device := C.fz_new_svg_device(ctx, out, width, height, C.FZ_SVG_TEXT_AS_PATH, 1)
+	defer C.fz_drop_device(ctx, device)
+
+	var cookie C.fz_cookie
+	C.pdf_run_page(ctx, page, device, &ctm, &cookie)
+
+	C.fz_close_device(ctx, device)
+	C.fz_close_output(ctx, out)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	return C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size)), nil
+}
+
+// stextAsID is like stextAs but also threads a page id through to the
+// printer, used by HTML/XHTML where the id distinguishes per-page ranges.
+func (f *Document) stextAsID(pageNumber int, print func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page, id int)) (string, error) {
+	return f.stextAs(pageNumber, func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page) {
+		print(ctx, out, stext, pageNumber)
+	})
+}
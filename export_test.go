@@ -0,0 +1,49 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	html, err := doc.HTML(0)
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(html))
+
+	xhtml, err := doc.XHTML(0)
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(xhtml))
+
+	all, err := doc.HTMLAll()
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(all))
+}
+
+func TestSVG(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	svg, err := doc.SVG(0)
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(svg))
+}
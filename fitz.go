@@ -41,11 +41,22 @@ var (
 
 // Document represents fitz document.
 type Document struct {
-	ctx       *C.struct_fz_context_s
+	ctx       *C.struct_fz_context_s // base context, only used to clone workers from and for one-off metadata calls
 	pdf       *C.struct_pdf_document_s
 	pageTotal int
 	objTotal  int
-	mtx       sync.Mutex //todo:delete lock to be more fast
+	mtx       sync.Mutex // protects pageTotal/objTotal against a concurrent Authenticate, which is the only place they're written after open
+
+	docID        uint32
+	docIDPtr     *C.uint
+	locks        [C.FZ_LOCK_MAX]sync.Mutex
+	poolMu       sync.Mutex
+	poolCond     *sync.Cond
+	free         []*C.struct_fz_context_s
+	liveContexts int
+	pendingDrops int
+	workers      int
+	repaired     bool
 }
 
 // Outline type.
@@ -75,7 +86,7 @@ func New(filename string) (f *Document, err error) {
 		return
 	}
 
-	f.ctx = (*C.struct_fz_context_s)(unsafe.Pointer(C.fz_new_context_imp(nil, nil, C.FZ_STORE_UNLIMITED, C.fz_version)))
+	f.ctx = newBaseContext(f)
 	if f.ctx == nil {
 		err = ErrCreateContext
 		return
@@ -106,7 +117,7 @@ func New(filename string) (f *Document, err error) {
 func NewFromMemory(b []byte) (f *Document, err error) {
 	f = &Document{}
 
-	f.ctx = (*C.struct_fz_context_s)(unsafe.Pointer(C.fz_new_context_imp(nil, nil, C.FZ_STORE_UNLIMITED, C.fz_version)))
+	f.ctx = newBaseContext(f)
 	if f.ctx == nil {
 		err = ErrCreateContext
 		return
@@ -151,53 +162,63 @@ func NewFromReader(r io.Reader) (f *Document, err error) {
 	return NewFromMemory(b)
 }
 
-// NumPage returns total number of pages in document.
+// NumPage returns total number of pages in document. Takes mtx, the same
+// lock Authenticate writes pageTotal/objTotal under, so this is safe to
+// call concurrently with Authenticate.
 func (f *Document) NumPage() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
 	return f.pageTotal
 }
 
-// NumObj returns total number of objects in document.
+// NumObj returns total number of objects in document. Takes mtx, the same
+// lock Authenticate writes pageTotal/objTotal under, so this is safe to
+// call concurrently with Authenticate.
 func (f *Document) NumObj() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
 	return f.objTotal
 }
 
 // Text returns text for given page number.  Index start at 0
 func (f *Document) Text(pageNumber int) (string, error) {
-	f.mtx.Lock()
-	defer f.mtx.Unlock()
-
 	if pageNumber < 0 || f.pageTotal <= pageNumber {
 		return "", ErrPageMissing
 	}
 
-	page := C.pdf_load_page(f.ctx, f.pdf, C.int(pageNumber))
-	defer C.fz_drop_page(f.ctx, (*C.fz_page)(unsafe.Pointer(page)))
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	page := C.pdf_load_page(ctx, f.pdf, C.int(pageNumber))
+	defer C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
 
 	var bounds C.fz_rect
-	C.pdf_bound_page(f.ctx, page, &bounds)
+	C.pdf_bound_page(ctx, page, &bounds)
 
 	var ctm C.fz_matrix
 	C.fz_scale(&ctm, C.float(1.0), C.float(1.0))
 
-	text := C.fz_new_stext_page(f.ctx, &bounds)
-	defer C.fz_drop_stext_page(f.ctx, text)
+	text := C.fz_new_stext_page(ctx, &bounds)
+	defer C.fz_drop_stext_page(ctx, text)
 
 	var opts C.fz_stext_options
 	opts.flags = 0
 
-	device := C.fz_new_stext_device(f.ctx, text, &opts)
-	C.fz_enable_device_hints(f.ctx, device, C.FZ_NO_CACHE)
-	defer C.fz_drop_device(f.ctx, device)
+	device := C.fz_new_stext_device(ctx, text, &opts)
+	C.fz_enable_device_hints(ctx, device, C.FZ_NO_CACHE)
+	defer C.fz_drop_device(ctx, device)
 
 	var cookie C.fz_cookie
-	C.pdf_run_page(f.ctx, page, device, &ctm, &cookie)
+	C.pdf_run_page(ctx, page, device, &ctm, &cookie)
 
-	C.fz_close_device(f.ctx, device)
+	C.fz_close_device(ctx, device)
 
-	buf := C.fz_new_buffer_from_stext_page(f.ctx, text)
-	defer C.fz_drop_buffer(f.ctx, buf)
+	buf := C.fz_new_buffer_from_stext_page(ctx, text)
+	defer C.fz_drop_buffer(ctx, buf)
 
-	str := C.GoString(C.fz_string_from_buffer(f.ctx, buf))
+	str := C.GoString(C.fz_string_from_buffer(ctx, buf))
 
 	return str, nil
 }
@@ -215,38 +236,38 @@ func (f *Document) Image(objNumber int) (image.Image, error) {
 // ImageBytes returns image.Image bytes encoded by png. The objNumber should between 1 ~ f.NumObj()
 // ImageBytes will be faster than Image
 func (f *Document) ImageBytes(objNumber int) ([]byte, error) {
-	f.mtx.Lock()
-	defer f.mtx.Unlock()
-
 	if objNumber <= 0 || f.objTotal <= objNumber {
 		return nil, ErrObjMissing
 	}
 
-	obj := C.pdf_load_object(f.ctx, f.pdf, C.int(objNumber))
-	if f.isImage(obj) {
-		return f.saveImage(objNumber), nil
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	obj := C.pdf_load_object(ctx, f.pdf, C.int(objNumber))
+	if f.isImage(ctx, obj) {
+		return f.saveImage(ctx, objNumber), nil
 	}
 
 	return nil, ErrNotImage
 }
 
-func (f *Document) isImage(obj *C.pdf_obj) bool {
-	objType := C.pdf_dict_get(f.ctx, obj, C.PDF_NAME_Subtype);
-	return C.int(1) == C.pdf_name_eq(f.ctx, objType, C.PDF_NAME_Image)
+func (f *Document) isImage(ctx *C.struct_fz_context_s, obj *C.pdf_obj) bool {
+	objType := C.pdf_dict_get(ctx, obj, C.PDF_NAME_Subtype);
+	return C.int(1) == C.pdf_name_eq(ctx, objType, C.PDF_NAME_Image)
 }
 
-func (f *Document) saveImage(objNumber int) []byte {
-	ref := C.pdf_new_indirect(f.ctx, f.pdf, C.int(objNumber), C.int(0))
-	defer C.pdf_drop_obj(f.ctx, ref)
+func (f *Document) saveImage(ctx *C.struct_fz_context_s, objNumber int) []byte {
+	ref := C.pdf_new_indirect(ctx, f.pdf, C.int(objNumber), C.int(0))
+	defer C.pdf_drop_obj(ctx, ref)
 
-	fzImg := C.pdf_load_image(f.ctx, f.pdf, ref)
-	defer C.fz_drop_image(f.ctx, fzImg)
+	fzImg := C.pdf_load_image(ctx, f.pdf, ref)
+	defer C.fz_drop_image(ctx, fzImg)
 
-	buf := C.fz_new_buffer_from_image_as_png(f.ctx, fzImg, nil)
-	defer C.fz_drop_buffer(f.ctx, buf)
+	buf := C.fz_new_buffer_from_image_as_png(ctx, fzImg, nil)
+	defer C.fz_drop_buffer(ctx, buf)
 
-	size := C.fz_buffer_storage(f.ctx, buf, nil)
-	str := C.GoStringN(C.fz_string_from_buffer(f.ctx, buf), C.int(size))
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	str := C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size))
 
 	return []byte(str)
 }
@@ -315,6 +336,7 @@ func (f *Document) Metadata() map[string]string {
 
 // Close closes the underlying fitz document.
 func (f *Document) Close() error {
+	f.closePool()
 	C.pdf_drop_document(f.ctx, f.pdf)
 	C.fz_drop_context(f.ctx)
 	return nil
@@ -0,0 +1,199 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Errors.
+var (
+	ErrNotEmbedded = errors.New("fitz: font/file is not embedded, no bytes to extract")
+)
+
+// Font describes a /Type /Font object found while walking the xref, in the
+// same spirit as the mupdfextract tool.
+type Font struct {
+	Num      int
+	Name     string // /BaseFont
+	Subtype  string // Type0, TrueType, Type1, MMType1, Type3, CIDFontType0, CIDFontType2
+	Encoding string
+	Embedded bool
+
+	doc     *Document
+	fileNum int
+}
+
+// Bytes returns the embedded font program (from /FontFile, /FontFile2 or
+// /FontFile3), or ErrNotEmbedded if the font has no embedded program.
+func (ft *Font) Bytes() ([]byte, error) {
+	if !ft.Embedded || ft.fileNum == 0 {
+		return nil, ErrNotEmbedded
+	}
+	return ft.doc.Stream(ft.fileNum, 0)
+}
+
+// EmbeddedFile describes a /Type /EmbeddedFile stream found while walking
+// the document's /EmbeddedFiles name tree (an attachment).
+type EmbeddedFile struct {
+	Num      int
+	Filename string
+	MIME     string // /Subtype
+	ModDate  string
+	Size     int64
+
+	doc *Document
+}
+
+// Bytes returns the embedded file's (decoded) contents.
+func (e *EmbeddedFile) Bytes() ([]byte, error) {
+	return e.doc.Stream(e.Num, 0)
+}
+
+// Fonts walks the xref and returns every /Type /Font object in the
+// document.
+func (f *Document) Fonts() ([]Font, error) {
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	var fonts []Font
+
+	for num := 1; num < f.objTotal; num++ {
+		obj := C.pdf_load_object(ctx, f.pdf, C.int(num))
+		if C.pdf_dict_get(ctx, obj, C.PDF_NAME_Type) == nil ||
+			C.pdf_name_eq(ctx, C.pdf_dict_get(ctx, obj, C.PDF_NAME_Type), C.PDF_NAME_Font) == 0 {
+			continue
+		}
+
+		font := Font{
+			Num:      num,
+			Name:     C.GoString(C.pdf_to_name(ctx, C.pdf_dict_get(ctx, obj, C.PDF_NAME_BaseFont))),
+			Subtype:  C.GoString(C.pdf_to_name(ctx, C.pdf_dict_get(ctx, obj, C.PDF_NAME_Subtype))),
+			Encoding: C.GoString(C.pdf_to_name(ctx, C.pdf_dict_get(ctx, obj, C.PDF_NAME_Encoding))),
+			doc:      f,
+		}
+
+		descriptor := C.pdf_dict_get(ctx, obj, C.PDF_NAME_FontDescriptor)
+		if descriptor == nil {
+			// CIDFonts carry the descriptor on their one /DescendantFonts entry.
+			descendants := C.pdf_dict_get(ctx, obj, C.PDF_NAME_DescendantFonts)
+			if descendants != nil && C.pdf_array_len(ctx, descendants) > 0 {
+				descriptor = C.pdf_dict_get(ctx, C.pdf_array_get(ctx, descendants, 0), C.PDF_NAME_FontDescriptor)
+			}
+		}
+
+		if descriptor != nil {
+			for _, name := range []*C.pdf_obj{C.PDF_NAME_FontFile, C.PDF_NAME_FontFile2, C.PDF_NAME_FontFile3} {
+				if file := C.pdf_dict_get(ctx, descriptor, name); file != nil {
+					font.Embedded = true
+					font.fileNum = int(C.pdf_to_num(ctx, file))
+					break
+				}
+			}
+		}
+
+		fonts = append(fonts, font)
+	}
+
+	return fonts, nil
+}
+
+// EmbeddedFiles returns every attachment listed in the document's
+// /Root/Names/EmbeddedFiles name tree. The filename is not stored on the
+// /Type /EmbeddedFile stream itself (per PDF 32000-1, 7.11.3/7.11.4) but on
+// the file specification dictionary that names it, so unlike Fonts this
+// walks that tree rather than the raw xref.
+func (f *Document) EmbeddedFiles() ([]EmbeddedFile, error) {
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	trailer := C.pdf_trailer(ctx, f.pdf)
+	if trailer == nil {
+		return nil, nil
+	}
+
+	root := C.pdf_dict_get(ctx, trailer, C.PDF_NAME_Root)
+	names := C.pdf_dict_get(ctx, root, C.PDF_NAME_Names)
+	tree := C.pdf_dict_get(ctx, names, C.PDF_NAME_EmbeddedFiles)
+	if tree == nil {
+		return nil, nil
+	}
+
+	var files []EmbeddedFile
+
+	var walk func(node *C.pdf_obj)
+	walk = func(node *C.pdf_obj) {
+		if node == nil {
+			return
+		}
+
+		if kids := C.pdf_dict_get(ctx, node, C.PDF_NAME_Kids); kids != nil {
+			for i := 0; i < int(C.pdf_array_len(ctx, kids)); i++ {
+				walk(C.pdf_array_get(ctx, kids, C.int(i)))
+			}
+			return
+		}
+
+		entries := C.pdf_dict_get(ctx, node, C.PDF_NAME_Names)
+		n := int(C.pdf_array_len(ctx, entries))
+		for i := 0; i+1 < n; i += 2 {
+			name := C.GoString(C.pdf_to_text_string(ctx, C.pdf_array_get(ctx, entries, C.int(i))))
+			filespec := C.pdf_array_get(ctx, entries, C.int(i+1))
+			if file, ok := f.embeddedFileFromSpec(ctx, name, filespec); ok {
+				files = append(files, file)
+			}
+		}
+	}
+
+	walk(tree)
+
+	return files, nil
+}
+
+// embeddedFileFromSpec resolves a /Type /Filespec dict (as found in a
+// /EmbeddedFiles name tree leaf) to the EmbeddedFile it names, preferring
+// /UF (unicode filename) over /F, falling back to the name-tree key itself.
+func (f *Document) embeddedFileFromSpec(ctx *C.struct_fz_context_s, treeName string, filespec *C.pdf_obj) (EmbeddedFile, bool) {
+	ef := C.pdf_dict_get(ctx, filespec, C.PDF_NAME_EF)
+	if ef == nil {
+		return EmbeddedFile{}, false
+	}
+
+	cUF := C.CString("UF")
+	defer C.free(unsafe.Pointer(cUF))
+
+	ref := C.pdf_dict_gets(ctx, ef, cUF)
+	if ref == nil {
+		ref = C.pdf_dict_get(ctx, ef, C.PDF_NAME_F)
+	}
+	if ref == nil || C.pdf_is_indirect(ctx, ref) == 0 {
+		return EmbeddedFile{}, false
+	}
+
+	stream := C.pdf_resolve_indirect(ctx, ref)
+
+	file := EmbeddedFile{
+		Num:      int(C.pdf_to_num(ctx, ref)),
+		Filename: treeName,
+		MIME:     C.GoString(C.pdf_to_name(ctx, C.pdf_dict_get(ctx, stream, C.PDF_NAME_Subtype))),
+		doc:      f,
+	}
+
+	if uf := C.pdf_dict_gets(ctx, filespec, cUF); uf != nil && C.pdf_is_string(ctx, uf) != 0 {
+		file.Filename = C.GoString(C.pdf_to_text_string(ctx, uf))
+	} else if fname := C.pdf_dict_get(ctx, filespec, C.PDF_NAME_F); fname != nil && C.pdf_is_string(ctx, fname) != 0 {
+		file.Filename = C.GoString(C.pdf_to_text_string(ctx, fname))
+	}
+
+	if params := C.pdf_dict_get(ctx, stream, C.PDF_NAME_Params); params != nil {
+		file.Size = int64(C.pdf_to_int(ctx, C.pdf_dict_get(ctx, params, C.PDF_NAME_Size)))
+		file.ModDate = C.GoString(C.pdf_to_text_string(ctx, C.pdf_dict_get(ctx, params, C.PDF_NAME_ModDate)))
+	}
+
+	return file, true
+}
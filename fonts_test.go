@@ -0,0 +1,54 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestFonts(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	fonts, err := doc.Fonts()
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, font := range fonts {
+		if font.Embedded {
+			if _, err := font.Bytes(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	fmt.Println("fonts:", len(fonts))
+}
+
+func TestEmbeddedFiles(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	files, err := doc.EmbeddedFiles()
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, file := range files {
+		if file.Filename == "" {
+			t.Error("expected embedded file to carry a filename from its Filespec dict")
+		}
+		if _, err := file.Bytes(); err != nil {
+			t.Error(err)
+		}
+	}
+	fmt.Println("embedded files:", len(files))
+}
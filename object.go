@@ -0,0 +1,200 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"errors"
+)
+
+// Errors.
+var (
+	ErrNoSuchObject = errors.New("fitz: no such object")
+	ErrNotStream    = errors.New("fitz: obj is not a stream")
+)
+
+// ObjKind discriminates the variant held by an Object.
+type ObjKind int
+
+// Object variants, mirroring the primitive PDF object types.
+const (
+	ObjNull ObjKind = iota
+	ObjBool
+	ObjInt
+	ObjReal
+	ObjString
+	ObjName
+	ObjArray
+	ObjDict
+	ObjIndirect
+)
+
+// Object is a Go-side tree reflecting a low-level PDF object (fz_obj/pdf_obj):
+// a dict, array, scalar, or indirect reference. Indirect references are not
+// followed automatically (dereference them with Document.Object(RefNum,
+// RefGen)) so that cyclic object graphs can be walked safely.
+type Object struct {
+	Kind ObjKind
+
+	Bool   bool
+	Int    int64
+	Real   float64
+	Str    string // holds both ObjString and ObjName values
+	Array  []Object
+	Dict   map[string]Object
+	RefNum int
+	RefGen int
+}
+
+// Object returns a Go tree for the indirect object numbered num, generation
+// gen. gen is usually 0.
+func (f *Document) Object(num, gen int) (Object, error) {
+	if num <= 0 || f.objTotal <= num {
+		return Object{}, ErrNoSuchObject
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	ref := C.pdf_new_indirect(ctx, f.pdf, C.int(num), C.int(gen))
+	defer C.pdf_drop_obj(ctx, ref)
+
+	resolved := C.pdf_resolve_indirect(ctx, ref)
+	return f.toObject(ctx, resolved), nil
+}
+
+// Trailer returns the document's trailer dictionary.
+func (f *Document) Trailer() (Object, error) {
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	trailer := C.pdf_trailer(ctx, f.pdf)
+	if trailer == nil {
+		return Object{}, ErrNoSuchObject
+	}
+
+	return f.toObject(ctx, trailer), nil
+}
+
+// Catalog returns the document catalog (the trailer's /Root entry).
+func (f *Document) Catalog() (Object, error) {
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	trailer := C.pdf_trailer(ctx, f.pdf)
+	if trailer == nil {
+		return Object{}, ErrNoSuchObject
+	}
+
+	root := C.pdf_resolve_indirect(ctx, C.pdf_dict_get(ctx, trailer, C.PDF_NAME_Root))
+	if root == nil {
+		return Object{}, ErrNoSuchObject
+	}
+
+	return f.toObject(ctx, root), nil
+}
+
+// Stream returns the decoded bytes of the stream belonging to indirect
+// object num (filters, e.g. FlateDecode, already applied).
+func (f *Document) Stream(num, gen int) ([]byte, error) {
+	return f.stream(num, gen, false)
+}
+
+// RawStream returns the undecoded bytes of the stream belonging to
+// indirect object num (filters, e.g. FlateDecode, not applied).
+func (f *Document) RawStream(num, gen int) ([]byte, error) {
+	return f.stream(num, gen, true)
+}
+
+func (f *Document) stream(num, gen int, raw bool) ([]byte, error) {
+	if num <= 0 || f.objTotal <= num {
+		return nil, ErrNoSuchObject
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	ref := C.pdf_new_indirect(ctx, f.pdf, C.int(num), C.int(gen))
+	defer C.pdf_drop_obj(ctx, ref)
+
+	if C.pdf_is_stream(ctx, ref) == 0 {
+		return nil, ErrNotStream
+	}
+
+	var stream *C.fz_stream
+	if raw {
+		stream = C.pdf_open_raw_stream_number(ctx, f.pdf, C.int(num))
+	} else {
+		stream = C.pdf_open_stream_number(ctx, f.pdf, C.int(num))
+	}
+	if stream == nil {
+		return nil, ErrNotStream
+	}
+	defer C.fz_drop_stream(ctx, stream)
+
+	buf := C.fz_read_all(ctx, stream, 4096)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	str := C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size))
+
+	return []byte(str), nil
+}
+
+// toObject walks a pdf_obj tree, converting it into an Object.
+func (f *Document) toObject(ctx *C.struct_fz_context_s, obj *C.pdf_obj) Object {
+	switch {
+	case obj == nil:
+		return Object{Kind: ObjNull}
+
+	// Checked before the type predicates below, which resolve indirect
+	// references themselves.
+	case C.pdf_is_indirect(ctx, obj) != 0:
+		return Object{
+			Kind:   ObjIndirect,
+			RefNum: int(C.pdf_to_num(ctx, obj)),
+			RefGen: int(C.pdf_to_gen(ctx, obj)),
+		}
+
+	case C.pdf_is_null(ctx, obj) != 0:
+		return Object{Kind: ObjNull}
+
+	case C.pdf_is_bool(ctx, obj) != 0:
+		return Object{Kind: ObjBool, Bool: C.pdf_to_bool(ctx, obj) != 0}
+
+	case C.pdf_is_int(ctx, obj) != 0:
+		return Object{Kind: ObjInt, Int: int64(C.pdf_to_int(ctx, obj))}
+
+	case C.pdf_is_real(ctx, obj) != 0:
+		return Object{Kind: ObjReal, Real: float64(C.pdf_to_real(ctx, obj))}
+
+	case C.pdf_is_name(ctx, obj) != 0:
+		return Object{Kind: ObjName, Str: C.GoString(C.pdf_to_name(ctx, obj))}
+
+	case C.pdf_is_string(ctx, obj) != 0:
+		return Object{Kind: ObjString, Str: C.GoString(C.pdf_to_text_string(ctx, obj))}
+
+	case C.pdf_is_array(ctx, obj) != 0:
+		n := int(C.pdf_array_len(ctx, obj))
+		arr := make([]Object, n)
+		for i := 0; i < n; i++ {
+			arr[i] = f.toObject(ctx, C.pdf_array_get(ctx, obj, C.int(i)))
+		}
+		return Object{Kind: ObjArray, Array: arr}
+
+	case C.pdf_is_dict(ctx, obj) != 0:
+		n := int(C.pdf_dict_len(ctx, obj))
+		dict := make(map[string]Object, n)
+		for i := 0; i < n; i++ {
+			key := C.GoString(C.pdf_to_name(ctx, C.pdf_dict_get_key(ctx, obj, C.int(i))))
+			dict[key] = f.toObject(ctx, C.pdf_dict_get_val(ctx, obj, C.int(i)))
+		}
+		return Object{Kind: ObjDict, Dict: dict}
+
+	default:
+		return Object{Kind: ObjNull}
+	}
+}
@@ -0,0 +1,42 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestObject(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	catalog, err := doc.Catalog()
+	if err != nil {
+		t.Error(err)
+	}
+	if catalog.Kind != ObjDict {
+		t.Errorf("expected catalog to be a dict, got %v", catalog.Kind)
+	}
+
+	trailer, err := doc.Trailer()
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(trailer.Dict))
+
+	for n := 1; n < doc.NumObj(); n++ {
+		obj, err := doc.Object(n, 0)
+		if err != nil {
+			t.Error(err)
+		}
+		if obj.Kind == ObjDict {
+			if _, err := doc.Stream(n, 0); err != nil && err != ErrNotStream {
+				t.Error(err)
+			}
+		}
+	}
+}
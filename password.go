@@ -0,0 +1,110 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"unsafe"
+)
+
+// Errors.
+var (
+	ErrAuthenticate = errors.New("fitz: cannot authenticate document")
+)
+
+// PDF permission bits, as defined by PDF_PERM_* in mupdf/pdf/document.h.
+const (
+	PermPrint         = C.PDF_PERM_PRINT
+	PermModify        = C.PDF_PERM_MODIFY
+	PermCopy          = C.PDF_PERM_COPY
+	PermAnnotate      = C.PDF_PERM_ANNOTATE
+	PermFormFillIn    = C.PDF_PERM_FORM
+	PermCopyForAccess = C.PDF_PERM_ACCESSIBILITY
+	PermAssemble      = C.PDF_PERM_ASSEMBLE
+	PermPrintHighRes  = C.PDF_PERM_PRINT_HQ
+)
+
+// NewWithPassword returns new fitz document from filename, authenticating
+// with password if the document is encrypted. After process, please do
+// Close() to release resource.
+func NewWithPassword(filename, password string) (f *Document, err error) {
+	f, err = New(filename)
+	if err != nil && err != ErrNeedsPassword {
+		return
+	}
+
+	return f, f.authenticate(password)
+}
+
+// NewFromMemoryWithPassword returns new fitz document from byte slice,
+// authenticating with password if the document is encrypted. Please do
+// Close() to release resource.
+func NewFromMemoryWithPassword(b []byte, password string) (f *Document, err error) {
+	f, err = NewFromMemory(b)
+	if err != nil && err != ErrNeedsPassword {
+		return
+	}
+
+	return f, f.authenticate(password)
+}
+
+// NewFromReaderWithPassword returns new fitz document from io.Reader,
+// authenticating with password if the document is encrypted.
+func NewFromReaderWithPassword(r io.Reader, password string) (f *Document, err error) {
+	b, e := ioutil.ReadAll(r)
+	if e != nil {
+		err = e
+		return
+	}
+
+	return NewFromMemoryWithPassword(b, password)
+}
+
+// Authenticate unlocks an encrypted document with password, trying it as
+// both the owner and user password. It is a no-op, returning nil, if the
+// document does not require a password.
+func (f *Document) Authenticate(password string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	return f.authenticate(password)
+}
+
+// authenticate runs pdf_authenticate_password and refreshes the page/object
+// counts, which read as 0 while the document is locked.
+func (f *Document) authenticate(password string) error {
+	if C.pdf_needs_password(f.ctx, f.pdf) == 0 {
+		return nil
+	}
+
+	cpassword := C.CString(password)
+	defer C.free(unsafe.Pointer(cpassword))
+
+	ok := C.pdf_authenticate_password(f.ctx, f.pdf, cpassword)
+	if int(ok) == 0 {
+		return ErrAuthenticate
+	}
+
+	f.pageTotal = int(C.pdf_count_pages(f.ctx, f.pdf))
+	f.objTotal = int(C.pdf_count_objects(f.ctx, f.pdf))
+
+	return nil
+}
+
+// IsEncrypted reports whether the document is protected by a password.
+func (f *Document) IsEncrypted() bool {
+	return C.pdf_needs_password(f.ctx, f.pdf) != 0
+}
+
+// Permissions returns the PDF_PERM_* bitmap describing what the document
+// allows (printing, copying, annotating, and so on) under its current
+// authentication.
+func (f *Document) Permissions() int {
+	return int(C.pdf_document_permissions(f.ctx, f.pdf))
+}
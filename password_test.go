@@ -0,0 +1,37 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "encrypted.pdf"))
+	if err != nil && err != ErrNeedsPassword {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	if !doc.IsEncrypted() {
+		t.Error("expected document to be encrypted")
+	}
+
+	if err := doc.Authenticate("owner"); err != nil {
+		t.Error(err)
+	}
+
+	fmt.Println(doc.NumPage(), doc.Permissions())
+}
+
+func TestNewWithPassword(t *testing.T) {
+	doc, err := NewWithPassword(filepath.Join("testdata", "encrypted.pdf"), "owner")
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	fmt.Println(doc.NumPage())
+}
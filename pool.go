@@ -0,0 +1,213 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <stdlib.h>
+
+extern void goFitzLock(unsigned int docID, int lock);
+extern void goFitzUnlock(unsigned int docID, int lock);
+
+static void fitz_lock(void *user, int lock) {
+	goFitzLock(*(unsigned int *)user, lock);
+}
+
+static void fitz_unlock(void *user, int lock) {
+	goFitzUnlock(*(unsigned int *)user, lock);
+}
+
+static fz_locks_context *fitz_new_locks_context(unsigned int *docID) {
+	fz_locks_context *locks = (fz_locks_context *)malloc(sizeof(fz_locks_context));
+	locks->user = docID;
+	locks->lock = fitz_lock;
+	locks->unlock = fitz_unlock;
+	return locks;
+}
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// docRegistry maps the docID handed to MuPDF's fz_locks_context back to the
+// Document it belongs to, so the lock/unlock callbacks (invoked from C, on
+// whichever goroutine is running a cloned context) can find the right lock
+// table.
+var (
+	docRegistryMu sync.Mutex
+	docRegistry   = make(map[uint32]*Document)
+	nextDocID     uint32
+)
+
+//export goFitzLock
+func goFitzLock(docID C.uint, lock C.int) {
+	docRegistryMu.Lock()
+	f := docRegistry[uint32(docID)]
+	docRegistryMu.Unlock()
+
+	if f != nil {
+		f.locks[int(lock)].Lock()
+	}
+}
+
+//export goFitzUnlock
+func goFitzUnlock(docID C.uint, lock C.int) {
+	docRegistryMu.Lock()
+	f := docRegistry[uint32(docID)]
+	docRegistryMu.Unlock()
+
+	if f != nil {
+		f.locks[int(lock)].Unlock()
+	}
+}
+
+// newBaseContext creates the base fz_context for f, wired up with a
+// fz_locks_context so that fz_clone_context'd worker contexts (see
+// WithWorkers) can safely share the same glyph/font/store caches.
+func newBaseContext(f *Document) *C.struct_fz_context_s {
+	return newBaseContextWithStore(f, C.FZ_STORE_UNLIMITED)
+}
+
+// newBaseContextWithStore is like newBaseContext but allows callers (see
+// NewWithOptions) to cap the store size instead of leaving it unlimited.
+func newBaseContextWithStore(f *Document, maxStore C.size_t) *C.struct_fz_context_s {
+	docRegistryMu.Lock()
+	nextDocID++
+	f.docID = nextDocID
+	docRegistry[f.docID] = f
+	docRegistryMu.Unlock()
+
+	f.docIDPtr = (*C.uint)(C.malloc(C.size_t(unsafe.Sizeof(C.uint(0)))))
+	*f.docIDPtr = C.uint(f.docID)
+
+	locks := C.fitz_new_locks_context(f.docIDPtr)
+
+	return (*C.struct_fz_context_s)(unsafe.Pointer(C.fz_new_context_imp(nil, locks, maxStore, C.fz_version)))
+}
+
+// WithWorkers configures the number of cloned fz_context workers used to
+// parallelize Text/RenderPage/ImageBytes, resizing the pool if it already
+// exists. Safe to call concurrently with Text/RenderPage/ImageBytes: growing
+// wakes any goroutine blocked waiting for a free context, and shrinking
+// below the number currently checked out just marks those as due to be
+// dropped (instead of recycled) once their caller releases them, rather
+// than ever touching a context that is still in use. By default the pool
+// is sized to runtime.GOMAXPROCS(0) on first use.
+func (f *Document) WithWorkers(n int) *Document {
+	f.poolMu.Lock()
+	if f.poolCond == nil {
+		f.poolCond = sync.NewCond(&f.poolMu)
+	}
+	f.resizePoolLocked(n)
+	f.poolMu.Unlock()
+
+	return f
+}
+
+// resizePoolLocked adjusts the pool to n live cloned contexts. Must be
+// called with poolMu held and f.poolCond already initialized.
+func (f *Document) resizePoolLocked(n int) {
+	if n < 1 {
+		n = 1
+	}
+
+	f.workers = n
+
+	switch diff := n - (f.liveContexts - f.pendingDrops); {
+	case diff > 0:
+		// Checked-out contexts still due to be dropped are kept instead,
+		// before any new ones are cloned.
+		kept := diff
+		if kept > f.pendingDrops {
+			kept = f.pendingDrops
+		}
+		f.pendingDrops -= kept
+		diff -= kept
+		for i := 0; i < diff; i++ {
+			f.free = append(f.free, C.fz_clone_context(f.ctx))
+		}
+		f.liveContexts += diff
+		f.poolCond.Broadcast()
+
+	case diff < 0:
+		toDrop := -diff
+		for toDrop > 0 && len(f.free) > 0 {
+			ctx := f.free[len(f.free)-1]
+			f.free = f.free[:len(f.free)-1]
+			C.fz_drop_context(ctx)
+			f.liveContexts--
+			toDrop--
+		}
+		// Any shortfall is currently checked out; releaseContext drops
+		// those instead of recycling them once their callers are done.
+		f.pendingDrops += toDrop
+	}
+}
+
+// acquireContext checks out a cloned context for exclusive use by the
+// calling goroutine, waiting for one to free up (or be created by a
+// concurrent WithWorkers) if none are currently idle. Unlike a channel
+// handed out by value, waiting here always re-checks the live f.free slice
+// under poolMu, so a concurrent resize can never strand a waiter on a
+// stale, swapped-out channel.
+func (f *Document) acquireContext() *C.struct_fz_context_s {
+	f.poolMu.Lock()
+	if f.poolCond == nil {
+		f.poolCond = sync.NewCond(&f.poolMu)
+	}
+	if f.workers == 0 {
+		f.resizePoolLocked(runtime.GOMAXPROCS(0))
+	}
+	for len(f.free) == 0 {
+		f.poolCond.Wait()
+	}
+	ctx := f.free[len(f.free)-1]
+	f.free = f.free[:len(f.free)-1]
+	f.poolMu.Unlock()
+
+	return ctx
+}
+
+// releaseContext returns a cloned context to the pool, unless a shrinking
+// WithWorkers call is still waiting to retire one, in which case it is
+// dropped instead.
+func (f *Document) releaseContext(ctx *C.struct_fz_context_s) {
+	f.poolMu.Lock()
+	if f.pendingDrops > 0 {
+		C.fz_drop_context(ctx)
+		f.liveContexts--
+		f.pendingDrops--
+	} else {
+		f.free = append(f.free, ctx)
+		f.poolCond.Signal()
+	}
+	f.poolMu.Unlock()
+}
+
+// closePool drops every cloned worker context and unregisters the document
+// from the lock-callback registry.
+func (f *Document) closePool() {
+	f.poolMu.Lock()
+	for len(f.free) > 0 {
+		ctx := f.free[len(f.free)-1]
+		f.free = f.free[:len(f.free)-1]
+		C.fz_drop_context(ctx)
+		f.liveContexts--
+	}
+	// Anything still checked out is on its own, released by its caller
+	// before or after Close; make sure it gets dropped rather than reused.
+	f.pendingDrops += f.liveContexts
+	f.liveContexts = 0
+	f.poolMu.Unlock()
+
+	docRegistryMu.Lock()
+	delete(docRegistry, f.docID)
+	docRegistryMu.Unlock()
+
+	if f.docIDPtr != nil {
+		C.free(unsafe.Pointer(f.docIDPtr))
+		f.docIDPtr = nil
+	}
+}
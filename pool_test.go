@@ -0,0 +1,85 @@
+package fitz
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestWithWorkers(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	doc.WithWorkers(4)
+
+	var wg sync.WaitGroup
+	for n := 0; n < doc.NumPage(); n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if _, err := doc.Text(n); err != nil {
+				t.Error(err)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+func TestWithWorkersConcurrentResize(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	doc.WithWorkers(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := doc.Text(i % doc.NumPage()); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+
+	// Grow the pool one worker at a time while the Text calls above are in flight, so
+	// acquireContext is forced to block and then be woken by a resize
+	// rather than by a same-sized release.
+	for n := 1; n <= 4; n++ {
+		doc.WithWorkers(n)
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkTextConcurrent(b *testing.B) {
+	doc, err := New(filepath.Join("testdata", "liusi.pdf"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer doc.Close()
+
+	doc.WithWorkers(runtime.GOMAXPROCS(0))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		for n := 0; n < doc.NumPage(); n++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				doc.Text(n)
+			}(n)
+		}
+		wg.Wait()
+	}
+}
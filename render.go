@@ -0,0 +1,126 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"unsafe"
+)
+
+// Colorspace selects the pixel format used when rasterizing a page.
+type Colorspace int
+
+// Colorspaces supported by RenderPage/RenderPageBytes.
+const (
+	ColorspaceRGB Colorspace = iota
+	ColorspaceGray
+	ColorspaceCMYK
+)
+
+// Errors.
+var (
+	ErrRenderPage = errors.New("fitz: cannot render page")
+)
+
+// fzColorspace returns the fz_colorspace matching cs.
+func (f *Document) fzColorspace(ctx *C.struct_fz_context_s, cs Colorspace) *C.fz_colorspace {
+	switch cs {
+	case ColorspaceGray:
+		return C.fz_device_gray(ctx)
+	case ColorspaceCMYK:
+		return C.fz_device_cmyk(ctx)
+	default:
+		return C.fz_device_rgb(ctx)
+	}
+}
+
+// RenderPage rasterizes the given page at the given DPI and returns it as an
+// image.Image. Index starts at 0. An optional clip rectangle (in unscaled
+// page points) may be given to render only a tile of the page.
+func (f *Document) RenderPage(pageNumber int, dpi float64, colorspace Colorspace, clip ...image.Rectangle) (image.Image, error) {
+	b, err := f.RenderPageBytes(pageNumber, dpi, colorspace, clip...)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewBuffer(b))
+	return img, err
+}
+
+// RenderPageBytes rasterizes the given page at the given DPI and returns the
+// result as PNG encoded bytes. RenderPageBytes will be faster than
+// RenderPage since it skips the round trip through image.Decode.
+func (f *Document) RenderPageBytes(pageNumber int, dpi float64, colorspace Colorspace, clip ...image.Rectangle) ([]byte, error) {
+	if pageNumber < 0 || f.pageTotal <= pageNumber {
+		return nil, ErrPageMissing
+	}
+
+	if dpi <= 0 {
+		dpi = 72
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	page := C.pdf_load_page(ctx, f.pdf, C.int(pageNumber))
+	defer C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
+
+	var bounds C.fz_rect
+	C.pdf_bound_page(ctx, page, &bounds)
+
+	zoom := C.float(dpi / 72.0)
+
+	var ctm C.fz_matrix
+	C.fz_scale(&ctm, zoom, zoom)
+
+	rect := bounds
+	if len(clip) > 0 && !clip[0].Empty() {
+		r := clip[0]
+		rect.x0 = C.float(r.Min.X)
+		rect.y0 = C.float(r.Min.Y)
+		rect.x1 = C.float(r.Max.X)
+		rect.y1 = C.float(r.Max.Y)
+	}
+
+	C.fz_transform_rect(&rect, &ctm)
+	var bbox C.fz_irect
+	C.fz_round_rect(&bbox, &rect)
+
+	pixmap := C.fz_new_pixmap_with_bbox(ctx, f.fzColorspace(ctx, colorspace), bbox, nil, 1)
+	if pixmap == nil {
+		return nil, ErrCreatePixmap
+	}
+	defer C.fz_drop_pixmap(ctx, pixmap)
+
+	C.fz_clear_pixmap_with_value(ctx, pixmap, 0xff)
+
+	// ctm is applied once, by pdf_run_page below; the draw device itself
+	// adds no further transform.
+	var identity C.fz_matrix
+	C.fz_scale(&identity, C.float(1.0), C.float(1.0))
+
+	device := C.fz_new_draw_device(ctx, &identity, pixmap)
+	if device == nil {
+		return nil, ErrRenderPage
+	}
+	defer C.fz_drop_device(ctx, device)
+
+	var cookie C.fz_cookie
+	C.pdf_run_page(ctx, page, device, &ctm, &cookie)
+
+	C.fz_close_device(ctx, device)
+
+	buf := C.fz_new_buffer_from_pixmap_as_png(ctx, pixmap, nil)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	str := C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size))
+
+	return []byte(str), nil
+}
@@ -0,0 +1,36 @@
+package fitz
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderPage(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	for n := 0; n < doc.NumPage(); n++ {
+		img, err := doc.RenderPage(n, 150, ColorspaceRGB)
+		if err != nil {
+			t.Error(err)
+		}
+
+		f, err := os.Create(filepath.Join("test", fmt.Sprintf("render-%03d.png", n)))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := png.Encode(f, img); err != nil {
+			t.Error(err)
+		}
+
+		f.Close()
+	}
+}
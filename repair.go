@@ -0,0 +1,242 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+
+static pdf_document *fitz_open_document_repair(fz_context *ctx, const char *filename, int *repaired) {
+	pdf_document *doc = NULL;
+	*repaired = 0;
+
+	fz_try(ctx) {
+		doc = pdf_open_document(ctx, filename);
+	}
+	fz_catch(ctx) {
+		doc = NULL;
+	}
+
+	if (doc == NULL) {
+		return NULL;
+	}
+
+	fz_try(ctx) {
+		pdf_count_pages(ctx, doc);
+	}
+	fz_catch(ctx) {
+		fz_try(ctx) {
+			pdf_repair_xref(ctx, doc);
+			pdf_repair_obj_stms(ctx, doc);
+			*repaired = 1;
+		}
+		fz_catch(ctx) {
+			pdf_drop_document(ctx, doc);
+			doc = NULL;
+		}
+	}
+
+	return doc;
+}
+
+static pdf_document *fitz_open_document_with_stream_repair(fz_context *ctx, fz_stream *stream, int *repaired) {
+	pdf_document *doc = NULL;
+	*repaired = 0;
+
+	fz_try(ctx) {
+		doc = pdf_open_document_with_stream(ctx, stream);
+	}
+	fz_catch(ctx) {
+		doc = NULL;
+	}
+
+	if (doc == NULL) {
+		return NULL;
+	}
+
+	fz_try(ctx) {
+		pdf_count_pages(ctx, doc);
+	}
+	fz_catch(ctx) {
+		fz_try(ctx) {
+			pdf_repair_xref(ctx, doc);
+			pdf_repair_obj_stms(ctx, doc);
+			*repaired = 1;
+		}
+		fz_catch(ctx) {
+			pdf_drop_document(ctx, doc);
+			doc = NULL;
+		}
+	}
+
+	return doc;
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"unsafe"
+)
+
+// ErrUnsupportedSrc is returned by NewWithOptions when src is neither a
+// filename (string) nor a document's raw bytes ([]byte).
+var ErrUnsupportedSrc = errors.New("fitz: src must be a filename (string) or document bytes ([]byte)")
+
+// Options configures NewWithOptions.
+type Options struct {
+	// Repair runs pdf_repair_xref/pdf_repair_obj_stms when the document
+	// opens but counting its pages fails, and records that it did (see
+	// WasRepaired). A document that pdf_open_document itself rejects is
+	// not repaired; opening it still fails with ErrOpenDocument.
+	Repair bool
+	// Password, if non-empty, is used to authenticate an encrypted
+	// document before page/object counts are read.
+	Password string
+	// MaxStore caps the fz_context store size in bytes. Zero means
+	// unlimited, matching New.
+	MaxStore int64
+	// Workers sizes the cloned-context pool, see Document.WithWorkers.
+	// Zero leaves the pool to its default (runtime.GOMAXPROCS(0)).
+	Workers int
+}
+
+// NewWithOptions returns a new fitz document from src, per opts. src must
+// be a filename (string) or the document's raw bytes ([]byte). After use,
+// please do Close() to release resources.
+func NewWithOptions(src interface{}, opts Options) (f *Document, err error) {
+	switch v := src.(type) {
+	case string:
+		return newWithOptionsFile(v, opts)
+	case []byte:
+		return newWithOptionsMemory(v, opts)
+	default:
+		return nil, ErrUnsupportedSrc
+	}
+}
+
+func newWithOptionsFile(filename string, opts Options) (f *Document, err error) {
+	f = &Document{}
+	filename, err = filepath.Abs(filename)
+	if err != nil {
+		return
+	}
+
+	if _, e := os.Stat(filename); e != nil {
+		err = ErrNoSuchFile
+		return
+	}
+
+	maxStore := C.size_t(C.FZ_STORE_UNLIMITED)
+	if opts.MaxStore > 0 {
+		maxStore = C.size_t(opts.MaxStore)
+	}
+
+	f.ctx = newBaseContextWithStore(f, maxStore)
+	if f.ctx == nil {
+		err = ErrCreateContext
+		return
+	}
+
+	C.fz_register_document_handlers(f.ctx)
+
+	cfilename := C.CString(filename)
+	defer C.free(unsafe.Pointer(cfilename))
+
+	if opts.Repair {
+		var repaired C.int
+		f.pdf = C.fitz_open_document_repair(f.ctx, cfilename, &repaired)
+		f.repaired = repaired != 0
+	} else {
+		f.pdf = C.pdf_open_document(f.ctx, cfilename)
+	}
+
+	if f.pdf == nil {
+		err = ErrOpenDocument
+		return
+	}
+
+	if C.pdf_needs_password(f.ctx, f.pdf) != 0 {
+		if opts.Password == "" {
+			err = ErrNeedsPassword
+			return
+		}
+		if err = f.authenticate(opts.Password); err != nil {
+			return
+		}
+	} else {
+		f.pageTotal = int(C.pdf_count_pages(f.ctx, f.pdf))
+		f.objTotal = int(C.pdf_count_objects(f.ctx, f.pdf))
+	}
+
+	if opts.Workers > 0 {
+		f.WithWorkers(opts.Workers)
+	}
+
+	return
+}
+
+func newWithOptionsMemory(b []byte, opts Options) (f *Document, err error) {
+	f = &Document{}
+
+	maxStore := C.size_t(C.FZ_STORE_UNLIMITED)
+	if opts.MaxStore > 0 {
+		maxStore = C.size_t(opts.MaxStore)
+	}
+
+	f.ctx = newBaseContextWithStore(f, maxStore)
+	if f.ctx == nil {
+		err = ErrCreateContext
+		return
+	}
+
+	C.fz_register_document_handlers(f.ctx)
+
+	data := (*C.uchar)(C.CBytes(b))
+
+	stream := C.fz_open_memory(f.ctx, data, C.size_t(len(b)))
+	if stream == nil {
+		err = ErrOpenMemory
+		return
+	}
+	defer C.fz_drop_stream(f.ctx, stream)
+
+	if opts.Repair {
+		var repaired C.int
+		f.pdf = C.fitz_open_document_with_stream_repair(f.ctx, stream, &repaired)
+		f.repaired = repaired != 0
+	} else {
+		f.pdf = C.pdf_open_document_with_stream(f.ctx, stream)
+	}
+
+	if f.pdf == nil {
+		err = ErrOpenDocument
+		return
+	}
+
+	if C.pdf_needs_password(f.ctx, f.pdf) != 0 {
+		if opts.Password == "" {
+			err = ErrNeedsPassword
+			return
+		}
+		if err = f.authenticate(opts.Password); err != nil {
+			return
+		}
+	} else {
+		f.pageTotal = int(C.pdf_count_pages(f.ctx, f.pdf))
+		f.objTotal = int(C.pdf_count_objects(f.ctx, f.pdf))
+	}
+
+	if opts.Workers > 0 {
+		f.WithWorkers(opts.Workers)
+	}
+
+	return
+}
+
+// WasRepaired reports whether the document's xref table had to be rebuilt
+// with pdf_repair_xref/pdf_repair_obj_stms when it was opened with
+// Options{Repair: true}.
+func (f *Document) WasRepaired() bool {
+	return f.repaired
+}
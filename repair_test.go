@@ -0,0 +1,21 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewWithOptionsRepair(t *testing.T) {
+	doc, err := NewWithOptions(filepath.Join("testdata", "corrupt.pdf"), Options{
+		Repair:  true,
+		Workers: 2,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	fmt.Println("repaired:", doc.WasRepaired(), "pages:", doc.NumPage())
+}
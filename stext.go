@@ -0,0 +1,279 @@
+package fitz
+
+/*
+#include <mupdf/fitz.h>
+#include <mupdf/pdf.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Errors.
+var (
+	ErrStructuredText = errors.New("fitz: cannot extract structured text")
+)
+
+// Block type discriminators, mirroring FZ_STEXT_BLOCK_*.
+const (
+	StextBlockText  = C.FZ_STEXT_BLOCK_TEXT
+	StextBlockImage = C.FZ_STEXT_BLOCK_IMAGE
+)
+
+// Rect is a floating point rectangle, since PDF coordinates are not
+// integral like image.Rectangle's.
+type Rect struct {
+	X0, Y0, X1, Y1 float64
+}
+
+// StextChar is a single character of a StextLine, with its glyph position
+// and the font it was drawn with.
+type StextChar struct {
+	Rune       rune
+	Bbox       Rect
+	OriginX    float64
+	OriginY    float64
+	Size       float64
+	FontName   string
+	FontBold   bool
+	FontItalic bool
+}
+
+// StextLine is a line of characters sharing a baseline within a StextBlock.
+type StextLine struct {
+	Bbox  Rect
+	WMode int
+	Chars []StextChar
+}
+
+// StextImage carries the placement matrix of an image block together with
+// the pixmap, PNG-encoded eagerly while the stext device's fz_image handle
+// is still alive (it does not survive past the StructuredText call that
+// produced it, so it cannot be looked up again later by object number).
+type StextImage struct {
+	Bbox   Rect
+	Matrix [6]float64
+	PNG    []byte
+}
+
+// StextBlock is either a run of text lines or an embedded image, as
+// discriminated by Type (StextBlockText/StextBlockImage).
+type StextBlock struct {
+	Type  int
+	Bbox  Rect
+	Lines []StextLine
+	Image *StextImage
+}
+
+// cRectToRect converts a fz_rect to a Rect.
+func cRectToRect(r C.fz_rect) Rect {
+	return Rect{
+		X0: float64(r.x0),
+		Y0: float64(r.y0),
+		X1: float64(r.x1),
+		Y1: float64(r.y1),
+	}
+}
+
+// cQuadToRect converts a fz_quad (used for per-character bounds, since
+// characters may be rotated) to its axis-aligned bounding Rect.
+func cQuadToRect(q C.fz_quad) Rect {
+	xs := [4]float64{float64(q.ul.x), float64(q.ur.x), float64(q.ll.x), float64(q.lr.x)}
+	ys := [4]float64{float64(q.ul.y), float64(q.ur.y), float64(q.ll.y), float64(q.lr.y)}
+
+	r := Rect{X0: xs[0], Y0: ys[0], X1: xs[0], Y1: ys[0]}
+	for i := 1; i < 4; i++ {
+		if xs[i] < r.X0 {
+			r.X0 = xs[i]
+		}
+		if xs[i] > r.X1 {
+			r.X1 = xs[i]
+		}
+		if ys[i] < r.Y0 {
+			r.Y0 = ys[i]
+		}
+		if ys[i] > r.Y1 {
+			r.Y1 = ys[i]
+		}
+	}
+	return r
+}
+
+// pngFromImage PNG-encodes img, mirroring Document.saveImage. img is only
+// valid for the lifetime of the stext device that produced it, so this
+// must run during the StructuredText walk, not lazily from a method on
+// StextImage.
+func pngFromImage(ctx *C.struct_fz_context_s, img *C.fz_image) []byte {
+	if img == nil {
+		return nil
+	}
+
+	buf := C.fz_new_buffer_from_image_as_png(ctx, img, nil)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	return []byte(C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size)))
+}
+
+// stextBlockTextBody reinterprets the fz_stext_block union as its text
+// variant (first_line/last_line).
+type stextBlockTextBody struct {
+	FirstLine *C.fz_stext_line
+	LastLine  *C.fz_stext_line
+}
+
+// stextBlockImageBody reinterprets the fz_stext_block union as its image
+// variant (transform/image).
+type stextBlockImageBody struct {
+	Transform C.fz_matrix
+	Image     *C.fz_image
+}
+
+// StructuredText returns the page's text as a tree of blocks, lines and
+// chars, preserving layout, font and bounding box information that the
+// flattened Text() discards. Index starts at 0.
+func (f *Document) StructuredText(pageNumber int) ([]StextBlock, error) {
+	if pageNumber < 0 || f.pageTotal <= pageNumber {
+		return nil, ErrPageMissing
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	page := C.pdf_load_page(ctx, f.pdf, C.int(pageNumber))
+	defer C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
+
+	var bounds C.fz_rect
+	C.pdf_bound_page(ctx, page, &bounds)
+
+	var ctm C.fz_matrix
+	C.fz_scale(&ctm, C.float(1.0), C.float(1.0))
+
+	stext := C.fz_new_stext_page(ctx, &bounds)
+	defer C.fz_drop_stext_page(ctx, stext)
+
+	var opts C.fz_stext_options
+	opts.flags = C.FZ_STEXT_PRESERVE_IMAGES
+
+	device := C.fz_new_stext_device(ctx, stext, &opts)
+	defer C.fz_drop_device(ctx, device)
+
+	var cookie C.fz_cookie
+	C.pdf_run_page(ctx, page, device, &ctm, &cookie)
+
+	C.fz_close_device(ctx, device)
+
+	var blocks []StextBlock
+
+	for cblock := stext.first_block; cblock != nil; cblock = cblock.next {
+		block := StextBlock{
+			Type: int(cblock._type),
+			Bbox: cRectToRect(cblock.bbox),
+		}
+
+		switch cblock._type {
+		case C.FZ_STEXT_BLOCK_IMAGE:
+			body := (*stextBlockImageBody)(unsafe.Pointer(&cblock.u[0]))
+			m := body.Transform
+			block.Image = &StextImage{
+				Bbox:   block.Bbox,
+				Matrix: [6]float64{float64(m.a), float64(m.b), float64(m.c), float64(m.d), float64(m.e), float64(m.f)},
+				PNG:    pngFromImage(ctx, body.Image),
+			}
+
+		case C.FZ_STEXT_BLOCK_TEXT:
+			body := (*stextBlockTextBody)(unsafe.Pointer(&cblock.u[0]))
+
+			for cline := body.FirstLine; cline != nil; cline = cline.next {
+				line := StextLine{
+					Bbox:  cRectToRect(cline.bbox),
+					WMode: int(cline.wmode),
+				}
+
+				for cchar := cline.first_char; cchar != nil; cchar = cchar.next {
+					line.Chars = append(line.Chars, StextChar{
+						Rune:       rune(cchar.c),
+						Bbox:       cQuadToRect(cchar.quad),
+						OriginX:    float64(cchar.origin.x),
+						OriginY:    float64(cchar.origin.y),
+						Size:       float64(cchar.size),
+						FontName:   C.GoString(C.fz_font_name(ctx, cchar.font)),
+						FontBold:   C.fz_font_is_bold(ctx, cchar.font) != 0,
+						FontItalic: C.fz_font_is_italic(ctx, cchar.font) != 0,
+					})
+				}
+
+				block.Lines = append(block.Lines, line)
+			}
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// StructuredTextJSON returns the page's structured text as MuPDF's own
+// JSON representation (fz_print_stext_page_as_json), useful for consumers
+// that already speak that format.
+func (f *Document) StructuredTextJSON(pageNumber int) (string, error) {
+	return f.stextAs(pageNumber, func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page) {
+		C.fz_print_stext_page_as_json(ctx, out, stext, 1)
+	})
+}
+
+// StructuredTextHTML returns the page's structured text as MuPDF's HTML
+// representation (fz_print_stext_page_as_html).
+func (f *Document) StructuredTextHTML(pageNumber int) (string, error) {
+	return f.stextAs(pageNumber, func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page) {
+		C.fz_print_stext_page_as_html(ctx, out, stext, 0)
+	})
+}
+
+// stextAs builds the fz_stext_page for pageNumber and hands it, along with
+// a buffer-backed fz_output, to print for serialization.
+func (f *Document) stextAs(pageNumber int, print func(ctx *C.struct_fz_context_s, out *C.fz_output, stext *C.fz_stext_page)) (string, error) {
+	if pageNumber < 0 || f.pageTotal <= pageNumber {
+		return "", ErrPageMissing
+	}
+
+	ctx := f.acquireContext()
+	defer f.releaseContext(ctx)
+
+	page := C.pdf_load_page(ctx, f.pdf, C.int(pageNumber))
+	defer C.fz_drop_page(ctx, (*C.fz_page)(unsafe.Pointer(page)))
+
+	var bounds C.fz_rect
+	C.pdf_bound_page(ctx, page, &bounds)
+
+	var ctm C.fz_matrix
+	C.fz_scale(&ctm, C.float(1.0), C.float(1.0))
+
+	stext := C.fz_new_stext_page(ctx, &bounds)
+	defer C.fz_drop_stext_page(ctx, stext)
+
+	var opts C.fz_stext_options
+	opts.flags = C.FZ_STEXT_PRESERVE_IMAGES | C.FZ_STEXT_PRESERVE_LIGATURES
+
+	device := C.fz_new_stext_device(ctx, stext, &opts)
+	defer C.fz_drop_device(ctx, device)
+
+	var cookie C.fz_cookie
+	C.pdf_run_page(ctx, page, device, &ctm, &cookie)
+
+	C.fz_close_device(ctx, device)
+
+	buf := C.fz_new_buffer(ctx, 8192)
+	defer C.fz_drop_buffer(ctx, buf)
+
+	out := C.fz_new_output_with_buffer(ctx, buf)
+	defer C.fz_drop_output(ctx, out)
+
+	print(ctx, out, stext)
+	C.fz_close_output(ctx, out)
+
+	size := C.fz_buffer_storage(ctx, buf, nil)
+	return C.GoStringN(C.fz_string_from_buffer(ctx, buf), C.int(size)), nil
+}
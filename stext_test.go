@@ -0,0 +1,49 @@
+package fitz
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestStructuredText(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	blocks, err := doc.StructuredText(0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	var chars int
+	for _, block := range blocks {
+		for _, line := range block.Lines {
+			chars += len(line.Chars)
+		}
+		if block.Type == StextBlockImage {
+			if block.Image == nil || len(block.Image.PNG) == 0 {
+				t.Error("expected image block to carry PNG-encoded bytes")
+			}
+		}
+	}
+	fmt.Println("blocks:", len(blocks), "chars:", chars)
+}
+
+func TestStructuredTextJSON(t *testing.T) {
+	doc, err := New(filepath.Join("testdata", "test.pdf"))
+	if err != nil {
+		t.Error(err)
+	}
+
+	defer doc.Close()
+
+	s, err := doc.StructuredTextJSON(0)
+	if err != nil {
+		t.Error(err)
+	}
+	fmt.Println(len(s))
+}